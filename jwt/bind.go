@@ -0,0 +1,54 @@
+// Tideland Go REST Server Library - JSON Web Token - Binding
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"encoding/json"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// BINDING
+//--------------------
+
+// Bind marshals the claims to JSON and unmarshals the result into v,
+// which has to be a pointer to a struct with the usual "json" tags.
+// It round-trips through encoding/json, so only the conversions of
+// encoding/json apply: numbers bind to numeric fields and time.Time
+// fields need RFC 3339 strings. Numeric strings are not converted to
+// integers and numeric timestamps are not converted to time.Time as
+// GetInt()/GetTime() do.
+func (c Claims) Bind(v interface{}) error {
+	b, err := c.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.Annotate(err, ErrStructBinding, errorMessages, v, err)
+	}
+	return nil
+}
+
+// From marshals v, which has to be a struct or a pointer to one with
+// the usual "json" tags, and unmarshals the result into the claims,
+// replacing their current content.
+func (c *Claims) From(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Annotate(err, ErrStructBinding, errorMessages, v, err)
+	}
+	*c = Claims{}
+	return c.UnmarshalJSON(b)
+}
+
+// EOF
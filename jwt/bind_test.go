@@ -0,0 +1,73 @@
+// Tideland Go REST Server Library - JSON Web Token - Unit Tests
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"testing"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// TESTS
+//--------------------
+
+// UserClaims is a typed view on a subset of the reserved and
+// custom claims used by TestBindFrom.
+type UserClaims struct {
+	Subject string   `json:"sub"`
+	Roles   []string `json:"roles"`
+	Exp     int64    `json:"exp"`
+}
+
+// TestBindFrom tests the round trip between Claims and a typed struct.
+func TestBindFrom(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing claims struct binding")
+	claims := jwt.NewClaims()
+	claims.SetSubject("user-1")
+	claims.Set("roles", []string{"admin", "editor"})
+	claims.Set("exp", 1234567890)
+	var user UserClaims
+	err := claims.Bind(&user)
+	assert.Nil(err)
+	assert.Equal(user.Subject, "user-1")
+	assert.Equal(user.Roles, []string{"admin", "editor"})
+	assert.Equal(user.Exp, int64(1234567890))
+	var roundtripped jwt.Claims
+	err = roundtripped.From(&user)
+	assert.Nil(err)
+	sub, ok := roundtripped.GetString("sub")
+	assert.True(ok)
+	assert.Equal(sub, "user-1")
+}
+
+// TestFromReplaces tests that From() replaces the current content of
+// the claims instead of merging into it.
+func TestFromReplaces(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing that From() replaces stale claims")
+	claims := jwt.NewClaims()
+	claims.Set("stale", "leftover")
+	user := UserClaims{Subject: "user-2"}
+	err := claims.From(&user)
+	assert.Nil(err)
+	ok := claims.Contains("stale")
+	assert.False(ok)
+	sub, ok := claims.GetString("sub")
+	assert.True(ok)
+	assert.Equal(sub, "user-2")
+}
+
+// EOF
@@ -0,0 +1,94 @@
+// Tideland Go REST Server Library - JSON Web Token - Cryptography
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+	"strings"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// HMAC
+//--------------------
+
+// verifyHMAC checks a HS256/HS384/HS512 signature.
+func verifyHMAC(alg string, secret, signingInput, signature []byte) error {
+	mac := hmac.New(newHasher(alg), secret)
+	mac.Write(signingInput)
+	expected := mac.Sum(nil)
+	if !hmac.Equal(expected, signature) {
+		return errors.New(ErrSignatureInvalid, errorMessages, alg)
+	}
+	return nil
+}
+
+// newHasher returns the hash constructor matching alg.
+func newHasher(alg string) func() hash.Hash {
+	switch {
+	case strings.HasSuffix(alg, "384"):
+		return sha512.New384
+	case strings.HasSuffix(alg, "512"):
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+//--------------------
+// RSA
+//--------------------
+
+// verifyRSA checks a RS256/RS384/RS512 signature.
+func verifyRSA(alg string, pub *rsa.PublicKey, signingInput, signature []byte) error {
+	h := hashForAlgorithm(alg)
+	hasher := h.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+	if err := rsa.VerifyPKCS1v15(pub, h, digest, signature); err != nil {
+		return errors.Annotate(err, ErrSignatureInvalid, errorMessages, alg)
+	}
+	return nil
+}
+
+//--------------------
+// ECDSA
+//--------------------
+
+// verifyECDSA checks a ES256/ES384/ES512 signature. The signature is
+// the concatenation of the R and S values, each zero-padded to the
+// curve's key size, as required by RFC 7518 §3.4.
+func verifyECDSA(alg string, pub *ecdsa.PublicKey, signingInput, signature []byte) error {
+	h := hashForAlgorithm(alg)
+	hasher := h.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+	keySize := (pub.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*keySize {
+		return errors.New(ErrSignatureInvalid, errorMessages, alg)
+	}
+	r := new(big.Int).SetBytes(signature[:keySize])
+	s := new(big.Int).SetBytes(signature[keySize:])
+	if !ecdsa.Verify(pub, digest, r, s) {
+		return errors.New(ErrSignatureInvalid, errorMessages, alg)
+	}
+	return nil
+}
+
+// EOF
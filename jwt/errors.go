@@ -0,0 +1,66 @@
+// Tideland Go REST Server Library - JSON Web Token - Errors
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// ERROR CODES
+//--------------------
+
+const (
+	ErrJSONMarshalling = iota + 1
+	ErrJSONUnmarshalling
+	ErrTokenExpired
+	ErrTokenNotYetValid
+	ErrIssuedInFuture
+	ErrAudienceMismatch
+	ErrIssuerMismatch
+	ErrSubjectMismatch
+	ErrInvalidToken
+	ErrUnsupportedAlgorithm
+	ErrKeyNotFound
+	ErrKeyTypeMismatch
+	ErrSignatureInvalid
+	ErrJWKSFetchFailed
+	ErrUnsupportedKeyType
+	ErrUnsupportedEncryption
+	ErrKeyManagementFailed
+	ErrDecryptionFailed
+	ErrStructBinding
+)
+
+var errorMessages = errors.Messages{
+	ErrJSONMarshalling:      "cannot marshal claims to JSON",
+	ErrJSONUnmarshalling:    "cannot unmarshal claims from JSON",
+	ErrTokenExpired:         "token is expired since %v",
+	ErrTokenNotYetValid:     "token is not valid before %v",
+	ErrIssuedInFuture:       "token has been issued in the future at %v",
+	ErrAudienceMismatch:     "token audience does not contain %q",
+	ErrIssuerMismatch:       "token issuer %q does not match expected %q",
+	ErrSubjectMismatch:      "token subject %q does not match expected %q",
+	ErrInvalidToken:         "invalid token: %v",
+	ErrUnsupportedAlgorithm: "unsupported or disallowed algorithm %q",
+	ErrKeyNotFound:          "no verification key found for kid %q",
+	ErrKeyTypeMismatch:      "key type does not match algorithm %q",
+	ErrSignatureInvalid:     "signature verification failed for algorithm %q",
+	ErrJWKSFetchFailed:      "cannot fetch JSON web key set from %q: %v",
+	ErrUnsupportedKeyType:    "unsupported JSON web key type %q",
+	ErrUnsupportedEncryption: "unsupported key management or content encryption algorithm %q/%q",
+	ErrKeyManagementFailed:   "cannot wrap or unwrap the content encryption key: %v",
+	ErrDecryptionFailed:      "cannot decrypt token content: %v",
+	ErrStructBinding:         "cannot bind claims to or from %T: %v",
+}
+
+// EOF
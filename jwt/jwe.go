@@ -0,0 +1,236 @@
+// Tideland Go REST Server Library - JSON Web Token - Encryption
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// ALGORITHMS
+//--------------------
+
+// KeyManagementAlgorithm identifies how a JWE content encryption key
+// is protected, the "alg" header of a JWE.
+type KeyManagementAlgorithm string
+
+// Key management algorithms supported by Encrypt() and Decrypt().
+const (
+	RSAOAEP KeyManagementAlgorithm = "RSA-OAEP"
+	Dir     KeyManagementAlgorithm = "dir"
+)
+
+// ContentEncryption identifies how the claims payload of a JWE is
+// encrypted, the "enc" header of a JWE.
+type ContentEncryption string
+
+// Content encryptions supported by Encrypt() and Decrypt().
+const (
+	A128GCM ContentEncryption = "A128GCM"
+	A256GCM ContentEncryption = "A256GCM"
+)
+
+// cekSize returns the content encryption key size in bytes for enc.
+func cekSize(enc ContentEncryption) (int, error) {
+	switch enc {
+	case A128GCM:
+		return 16, nil
+	case A256GCM:
+		return 32, nil
+	}
+	return 0, errors.New(ErrUnsupportedEncryption, errorMessages, "", enc)
+}
+
+//--------------------
+// HEADER
+//--------------------
+
+// jweHeader is the decoded protected header of a JWE.
+type jweHeader struct {
+	Algorithm string `json:"alg"`
+	Enc       string `json:"enc"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+//--------------------
+// ENCRYPT
+//--------------------
+
+// Encrypt encrypts claims into a five segment compact JWE token using
+// alg to manage the content encryption key and enc to encrypt the
+// claims with it. key has to match alg: a *rsa.PublicKey for RSA-OAEP,
+// which wraps a freshly generated content encryption key, or a []byte
+// of the right size for dir, which is used as the content encryption
+// key itself.
+func Encrypt(claims Claims, key interface{}, alg KeyManagementAlgorithm, enc ContentEncryption) (string, error) {
+	size, err := cekSize(enc)
+	if err != nil {
+		return "", err
+	}
+	var cek []byte
+	var encryptedKey []byte
+	switch alg {
+	case Dir:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) != size {
+			return "", errors.New(ErrKeyTypeMismatch, errorMessages, alg)
+		}
+		cek = secret
+	case RSAOAEP:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return "", errors.New(ErrKeyTypeMismatch, errorMessages, alg)
+		}
+		cek = make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+			return "", errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+		}
+		encryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, cek, nil)
+		if err != nil {
+			return "", errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+		}
+	default:
+		return "", errors.New(ErrUnsupportedEncryption, errorMessages, alg, enc)
+	}
+	header := jweHeader{Algorithm: string(alg), Enc: string(enc)}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", errors.Annotate(err, ErrJSONMarshalling, errorMessages)
+	}
+	payload, err := claims.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+	}
+	aad := []byte(encodeSegment(headerJSON))
+	sealed := aead.Seal(nil, iv, payload, aad)
+	tagSize := aead.Overhead()
+	ciphertext, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+	return strings.Join([]string{
+		encodeSegment(headerJSON),
+		encodeSegment(encryptedKey),
+		encodeSegment(iv),
+		encodeSegment(ciphertext),
+		encodeSegment(tag),
+	}, "."), nil
+}
+
+//--------------------
+// DECRYPT
+//--------------------
+
+// Decrypt decodes a five segment compact JWE token produced by Encrypt,
+// unwrapping its content encryption key via resolver and returning the
+// decrypted claims.
+func Decrypt(token string, resolver KeyResolver) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, errors.New(ErrInvalidToken, errorMessages, "not a five segment JWE")
+	}
+	headerJSON, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	var h jweHeader
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages)
+	}
+	key, err := resolver.Key(h.KeyID, h.Algorithm)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrKeyNotFound, errorMessages, h.KeyID)
+	}
+	encryptedKey, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	size, err := cekSize(ContentEncryption(h.Enc))
+	if err != nil {
+		return nil, err
+	}
+	var cek []byte
+	switch KeyManagementAlgorithm(h.Algorithm) {
+	case Dir:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) != size {
+			return nil, errors.New(ErrKeyTypeMismatch, errorMessages, h.Algorithm)
+		}
+		cek = secret
+	case RSAOAEP:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New(ErrKeyTypeMismatch, errorMessages, h.Algorithm)
+		}
+		cek, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, encryptedKey, nil)
+		if err != nil {
+			return nil, errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+		}
+	default:
+		return nil, errors.New(ErrUnsupportedEncryption, errorMessages, h.Algorithm, h.Enc)
+	}
+	aead, err := newAEAD(cek)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := decodeSegment(parts[3])
+	if err != nil {
+		return nil, err
+	}
+	tag, err := decodeSegment(parts[4])
+	if err != nil {
+		return nil, err
+	}
+	aad := []byte(parts[0])
+	plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), aad)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrDecryptionFailed, errorMessages, err)
+	}
+	claims := NewClaims()
+	if err := json.Unmarshal(plaintext, &claims); err != nil {
+		return nil, errors.Annotate(err, ErrJSONUnmarshalling, errorMessages)
+	}
+	return claims, nil
+}
+
+// newAEAD builds the AES-GCM cipher used for content encryption.
+func newAEAD(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrKeyManagementFailed, errorMessages, err)
+	}
+	return aead, nil
+}
+
+// EOF
@@ -0,0 +1,75 @@
+// Tideland Go REST Server Library - JSON Web Token - Unit Tests
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestEncryptDecryptDir tests the JWE round trip using the "dir"
+// key management algorithm.
+func TestEncryptDecryptDir(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing JWE round trip with dir key management")
+	secret := make([]byte, 16)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	claims := jwt.NewClaims()
+	claims.SetSubject("user-1")
+	token, err := jwt.Encrypt(claims, secret, jwt.Dir, jwt.A128GCM)
+	assert.Nil(err)
+	decoded, err := jwt.Decrypt(token, jwt.StaticKeyResolver(secret))
+	assert.Nil(err)
+	sub, ok := decoded.Subject()
+	assert.True(ok)
+	assert.Equal(sub, "user-1")
+	// A wrong key has to fail.
+	_, err = jwt.Decrypt(token, jwt.StaticKeyResolver(make([]byte, 16)))
+	assert.ErrorMatch(err, ".*decrypt.*")
+}
+
+// TestEncryptDecryptRSAOAEP tests the JWE round trip using the
+// "RSA-OAEP" key management algorithm to wrap the content encryption
+// key.
+func TestEncryptDecryptRSAOAEP(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing JWE round trip with RSA-OAEP key management")
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(err)
+	claims := jwt.NewClaims()
+	claims.SetSubject("user-1")
+	token, err := jwt.Encrypt(claims, &priv.PublicKey, jwt.RSAOAEP, jwt.A256GCM)
+	assert.Nil(err)
+	decoded, err := jwt.Decrypt(token, jwt.StaticKeyResolver(priv))
+	assert.Nil(err)
+	sub, ok := decoded.Subject()
+	assert.True(ok)
+	assert.Equal(sub, "user-1")
+	// A wrong private key has to fail to unwrap the content key.
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(err)
+	_, err = jwt.Decrypt(token, jwt.StaticKeyResolver(other))
+	assert.NotNil(err)
+}
+
+// EOF
@@ -0,0 +1,261 @@
+// Tideland Go REST Server Library - JSON Web Token - JWKS
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// JWK / JWKS DECODING
+//--------------------
+
+// jwk is a single entry of a JSON Web Key Set as defined by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// jwkSet is the "keys" envelope of a JSON Web Key Set document.
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// oidcDiscovery is the subset of an OpenID Connect discovery document
+// needed to locate the JWKS endpoint.
+type oidcDiscovery struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// key converts a jwk into a *rsa.PublicKey, *ecdsa.PublicKey, or a
+// []byte HMAC secret, keyed by its "kty".
+func (k jwk) key() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64BigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64BigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, err := curveForName(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeBase64BigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64BigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, errors.Annotate(err, ErrInvalidToken, errorMessages)
+		}
+		return secret, nil
+	}
+	return nil, errors.New(ErrUnsupportedKeyType, errorMessages, k.Kty)
+}
+
+// decodeBase64BigInt decodes a base64url encoded big-endian integer.
+func decodeBase64BigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// curveForName maps a JWK "crv" value to its elliptic.Curve.
+func curveForName(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	}
+	return nil, errors.New(ErrUnsupportedKeyType, errorMessages, crv)
+}
+
+//--------------------
+// JWKS RESOLVER
+//--------------------
+
+// JWKSResolver is a KeyResolver fetching its keys from a remote JSON
+// Web Key Set endpoint. Keys are cached for TTL. Once TTL has elapsed
+// a known "kid" is still served from the stale cache while a refresh
+// is kicked off in the background; an unknown "kid" instead blocks on
+// a synchronous refresh, since only a fresh key set can tell whether
+// it actually exists.
+type JWKSResolver struct {
+	jwksURL string
+	ttl     time.Duration
+	client  *http.Client
+
+	mu         sync.RWMutex
+	keys       map[string]jwk
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+// NewJWKSResolver returns a JWKSResolver fetching keys from jwksURL,
+// a JSON Web Key Set document as served by most identity providers.
+// Keys are cached for ttl.
+func NewJWKSResolver(jwksURL string, ttl time.Duration) *JWKSResolver {
+	return &JWKSResolver{
+		jwksURL: jwksURL,
+		ttl:     ttl,
+		client:  http.DefaultClient,
+	}
+}
+
+// NewOIDCResolver discovers the JWKS endpoint of an OpenID Connect
+// provider via its "/.well-known/openid-configuration" document and
+// returns a JWKSResolver fetching from it. Keys are cached for ttl.
+// client is used for both the discovery request and all subsequent
+// key set fetches; a nil client defaults to http.DefaultClient.
+func NewOIDCResolver(issuerURL string, ttl time.Duration, client *http.Client) (*JWKSResolver, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	discoveryURL := issuerURL + "/.well-known/openid-configuration"
+	resp, err := client.Get(discoveryURL)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrJWKSFetchFailed, errorMessages, discoveryURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(ErrJWKSFetchFailed, errorMessages, discoveryURL, "unexpected status "+resp.Status)
+	}
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, errors.Annotate(err, ErrJWKSFetchFailed, errorMessages, discoveryURL, err)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New(ErrJWKSFetchFailed, errorMessages, discoveryURL, "no jwks_uri in discovery document")
+	}
+	resolver := NewJWKSResolver(discovery.JWKSURI, ttl)
+	resolver.SetHTTPClient(client)
+	return resolver, nil
+}
+
+// SetHTTPClient overrides the http.Client used to fetch the key set.
+func (r *JWKSResolver) SetHTTPClient(client *http.Client) *JWKSResolver {
+	r.client = client
+	return r
+}
+
+// Key implements KeyResolver.
+func (r *JWKSResolver) Key(kid, alg string) (interface{}, error) {
+	k, found, stale := r.cached(kid)
+	switch {
+	case found && !stale:
+		return k.key()
+	case found && stale:
+		// Serve the stale key immediately, refresh for next time.
+		r.refreshAsync()
+		return k.key()
+	}
+	// Unknown kid: only a fresh key set can tell whether it exists,
+	// so block on a synchronous refresh.
+	if err := r.refresh(); err != nil {
+		return nil, err
+	}
+	k, found, _ = r.cached(kid)
+	if !found {
+		return nil, errors.New(ErrKeyNotFound, errorMessages, kid)
+	}
+	return k.key()
+}
+
+// cached looks kid up in the cache. found reports whether kid is
+// currently known; stale reports whether the cache's TTL has elapsed.
+func (r *JWKSResolver) cached(kid string) (k jwk, found, stale bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, found = r.keys[kid]
+	stale = r.keys == nil || time.Since(r.fetchedAt) > r.ttl
+	return k, found, stale
+}
+
+// refreshAsync triggers a background refresh unless one is already
+// running.
+func (r *JWKSResolver) refreshAsync() {
+	r.mu.Lock()
+	if r.refreshing {
+		r.mu.Unlock()
+		return
+	}
+	r.refreshing = true
+	r.mu.Unlock()
+	go func() {
+		r.refresh()
+		r.mu.Lock()
+		r.refreshing = false
+		r.mu.Unlock()
+	}()
+}
+
+// refresh fetches and caches the current key set.
+func (r *JWKSResolver) refresh() error {
+	resp, err := r.client.Get(r.jwksURL)
+	if err != nil {
+		return errors.Annotate(err, ErrJWKSFetchFailed, errorMessages, r.jwksURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(ErrJWKSFetchFailed, errorMessages, r.jwksURL, "unexpected status "+resp.Status)
+	}
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return errors.Annotate(err, ErrJWKSFetchFailed, errorMessages, r.jwksURL, err)
+	}
+	keys := make(map[string]jwk, len(set.Keys))
+	for _, k := range set.Keys {
+		keys[k.Kid] = k
+	}
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// EOF
@@ -0,0 +1,103 @@
+// Tideland Go REST Server Library - JSON Web Token - Unit Tests
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestJWKSResolverRSA tests resolving a RSA key from a JWKS endpoint.
+func TestJWKSResolverRSA(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing JWKS resolver with a RSA key")
+	body := `{"keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	resolver := jwt.NewJWKSResolver(server.URL, time.Minute)
+	key, err := resolver.Key("key-1", "RS256")
+	assert.Nil(err)
+	assert.NotNil(key)
+	_, ok := key.(interface{ Size() int })
+	assert.True(ok)
+	// Unknown kid has to fail after a refresh attempt.
+	_, err = resolver.Key("unknown", "RS256")
+	assert.ErrorMatch(err, ".*key.*")
+}
+
+// TestJWKSResolverBadStatus tests that a non-200 JWKS response is
+// rejected instead of being decoded as an empty key set.
+func TestJWKSResolverBadStatus(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing JWKS resolver with a non-200 response")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<html>internal error</html>"))
+	}))
+	defer server.Close()
+	resolver := jwt.NewJWKSResolver(server.URL, time.Minute)
+	_, err := resolver.Key("key-1", "RS256")
+	assert.ErrorMatch(err, ".*status.*")
+}
+
+// TestOIDCResolverUsesConfiguredClient tests that NewOIDCResolver
+// uses the client passed to it for both the discovery request and
+// the subsequent key set fetches.
+func TestOIDCResolverUsesConfiguredClient(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing that the OIDC resolver honors its configured client")
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			w.Write([]byte(`{"jwks_uri":"` + "http://" + r.Host + `/jwks"}`))
+			return
+		}
+		w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`))
+	}))
+	defer server.Close()
+	client := &http.Client{
+		Transport: countingTransport{count: &requests, base: http.DefaultTransport},
+	}
+	resolver, err := jwt.NewOIDCResolver(server.URL, time.Minute, client)
+	assert.Nil(err)
+	_, err = resolver.Key("key-1", "RS256")
+	assert.Nil(err)
+	// One request for discovery, one for the key set, both through
+	// the configured client's transport.
+	assert.Equal(requests, 2)
+}
+
+// countingTransport counts the requests routed through it while
+// delegating the actual round trip to base.
+type countingTransport struct {
+	count *int
+	base  http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.count++
+	return t.base.RoundTrip(req)
+}
+
+// EOF
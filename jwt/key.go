@@ -0,0 +1,42 @@
+// Tideland Go REST Server Library - JSON Web Token - Keys
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// KEY RESOLVER
+//--------------------
+
+// KeyResolver looks up the key to verify a token signature. kid and
+// alg are the "kid" and "alg" fields of the token header, kid may be
+// empty if the token does not carry one. The returned key has to
+// match alg: a []byte for the HMAC family, a *rsa.PublicKey for RSA,
+// or a *ecdsa.PublicKey for ECDSA. When used by Decrypt() the key
+// unwraps the content encryption key instead: a *rsa.PrivateKey for
+// RSA-OAEP, or a []byte for dir.
+type KeyResolver interface {
+	Key(kid, alg string) (interface{}, error)
+}
+
+// KeyResolverFunc allows the use of an ordinary function as KeyResolver.
+type KeyResolverFunc func(kid, alg string) (interface{}, error)
+
+// Key implements KeyResolver.
+func (f KeyResolverFunc) Key(kid, alg string) (interface{}, error) {
+	return f(kid, alg)
+}
+
+// StaticKeyResolver returns a KeyResolver always resolving to the
+// same key, regardless of kid or alg. It is useful when a service
+// talks to a single, statically configured signer.
+func StaticKeyResolver(key interface{}) KeyResolver {
+	return KeyResolverFunc(func(kid, alg string) (interface{}, error) {
+		return key, nil
+	})
+}
+
+// EOF
@@ -0,0 +1,146 @@
+// Tideland Go REST Server Library - JSON Web Token - Token
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// HEADER
+//--------------------
+
+// header is the decoded JOSE header of a token.
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+//--------------------
+// ENCODING HELPERS
+//--------------------
+
+// encodeSegment base64url-encodes a token segment without padding.
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSegment base64url-decodes a token segment without padding.
+func decodeSegment(segment string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages)
+	}
+	return data, nil
+}
+
+//--------------------
+// DECODE (JWS)
+//--------------------
+
+// Decode splits a compact JWS token, resolves the verification key
+// for its "kid"/"alg" header via resolver, checks the signature, and
+// returns the decoded claims. It never accepts "alg: none".
+func Decode(token string, resolver KeyResolver) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New(ErrInvalidToken, errorMessages, "not a three segment JWS")
+	}
+	headerJSON, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages)
+	}
+	if h.Algorithm == "" || strings.EqualFold(h.Algorithm, "none") {
+		return nil, errors.New(ErrUnsupportedAlgorithm, errorMessages, h.Algorithm)
+	}
+	key, err := resolver.Key(h.KeyID, h.Algorithm)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrKeyNotFound, errorMessages, h.KeyID)
+	}
+	signingInput := parts[0] + "." + parts[1]
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(h.Algorithm, key, []byte(signingInput), signature); err != nil {
+		return nil, err
+	}
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	claims := NewClaims()
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.Annotate(err, ErrJSONUnmarshalling, errorMessages)
+	}
+	return claims, nil
+}
+
+//--------------------
+// SIGNATURE VERIFICATION
+//--------------------
+
+// verifySignature checks signature over signingInput for alg using key.
+// It rejects any mismatch between the algorithm family and the key type.
+func verifySignature(alg string, key interface{}, signingInput, signature []byte) error {
+	switch alg {
+	case "HS256", "HS384", "HS512":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errors.New(ErrKeyTypeMismatch, errorMessages, alg)
+		}
+		return verifyHMAC(alg, secret, signingInput, signature)
+	case "RS256", "RS384", "RS512":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New(ErrKeyTypeMismatch, errorMessages, alg)
+		}
+		return verifyRSA(alg, pub, signingInput, signature)
+	case "ES256", "ES384", "ES512":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New(ErrKeyTypeMismatch, errorMessages, alg)
+		}
+		return verifyECDSA(alg, pub, signingInput, signature)
+	}
+	return errors.New(ErrUnsupportedAlgorithm, errorMessages, alg)
+}
+
+// hashForAlgorithm returns the crypto.Hash used by the "256"/"384"/"512"
+// suffixed algorithms.
+func hashForAlgorithm(alg string) crypto.Hash {
+	switch {
+	case strings.HasSuffix(alg, "384"):
+		return crypto.SHA384
+	case strings.HasSuffix(alg, "512"):
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// The per-family signature checks (verifyHMAC, verifyRSA, verifyECDSA)
+// live in crypto.go so this file stays focused on the JWS framing.
+
+// EOF
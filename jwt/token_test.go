@@ -0,0 +1,76 @@
+// Tideland Go REST Server Library - JSON Web Token - Unit Tests
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// HELPERS
+//--------------------
+
+// makeHS256 builds a compact HS256 JWS token for claims signed with secret.
+func makeHS256(claims jwt.Claims, secret []byte) string {
+	header := map[string]string{"alg": "HS256", "typ": "JWT"}
+	headerJSON, _ := json.Marshal(header)
+	payloadJSON, _ := claims.MarshalJSON()
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+		base64.RawURLEncoding.EncodeToString(payloadJSON)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	return signingInput + "." + signature
+}
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestDecodeHMAC tests decoding and verifying a HS256 signed token.
+func TestDecodeHMAC(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing HS256 token decoding")
+	secret := []byte("top-secret")
+	claims := jwt.NewClaims()
+	claims.SetSubject("user-1")
+	token := makeHS256(claims, secret)
+	decoded, err := jwt.Decode(token, jwt.StaticKeyResolver(secret))
+	assert.Nil(err)
+	sub, ok := decoded.Subject()
+	assert.True(ok)
+	assert.Equal(sub, "user-1")
+	// Wrong secret has to fail.
+	_, err = jwt.Decode(token, jwt.StaticKeyResolver([]byte("wrong-secret")))
+	assert.ErrorMatch(err, ".*signature.*")
+}
+
+// TestDecodeRejectsNone tests that "alg: none" tokens are rejected.
+func TestDecodeRejectsNone(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing rejection of alg none tokens")
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-1"}`))
+	token := header + "." + payload + "."
+	_, err := jwt.Decode(token, jwt.StaticKeyResolver([]byte("secret")))
+	assert.ErrorMatch(err, ".*algorithm.*")
+}
+
+// EOF
@@ -0,0 +1,110 @@
+// Tideland Go REST Server Library - JSON Web Token - Validation
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"time"
+
+	"github.com/tideland/golib/errors"
+)
+
+//--------------------
+// VALIDATE OPTIONS
+//--------------------
+
+// ValidateOptions controls how Validate() checks the reserved
+// claims of a token. The zero value only checks "exp", "nbf",
+// and "iat" without any clock-skew leeway and without checking
+// issuer, subject, or audience.
+type ValidateOptions struct {
+	// ExpLeeway is the clock-skew tolerance applied when checking
+	// the "exp" claim. A token is only considered expired once
+	// now is after exp + ExpLeeway.
+	ExpLeeway time.Duration
+
+	// NbfLeeway is the clock-skew tolerance applied when checking
+	// the "nbf" claim and, for the same reason, the "iat" claim.
+	// A token is only considered not yet valid when now is before
+	// nbf - NbfLeeway, and only considered issued in the future
+	// when now is before iat - NbfLeeway.
+	NbfLeeway time.Duration
+
+	// Issuer, when not empty, has to match the "iss" claim.
+	Issuer string
+
+	// Subject, when not empty, has to match the "sub" claim.
+	Subject string
+
+	// Audience, when not empty, has to be contained in the
+	// "aud" claim.
+	Audience string
+}
+
+//--------------------
+// VALIDATE
+//--------------------
+
+// Validate checks the reserved claims of claims against now
+// using opts. It returns the first violation it encounters.
+func Validate(claims Claims, now time.Time, opts ValidateOptions) error {
+	if exp, ok := claims.Expiration(); ok {
+		if now.After(exp.Add(opts.ExpLeeway)) {
+			return errors.New(ErrTokenExpired, errorMessages, exp)
+		}
+	}
+	if nbf, ok := claims.NotBefore(); ok {
+		if now.Before(nbf.Add(-opts.NbfLeeway)) {
+			return errors.New(ErrTokenNotYetValid, errorMessages, nbf)
+		}
+	}
+	if iat, ok := claims.IssuedAt(); ok {
+		if now.Before(iat.Add(-opts.NbfLeeway)) {
+			return errors.New(ErrIssuedInFuture, errorMessages, iat)
+		}
+	}
+	if opts.Issuer != "" {
+		if iss, ok := claims.Issuer(); !ok || iss != opts.Issuer {
+			return errors.New(ErrIssuerMismatch, errorMessages, iss, opts.Issuer)
+		}
+	}
+	if opts.Subject != "" {
+		if sub, ok := claims.Subject(); !ok || sub != opts.Subject {
+			return errors.New(ErrSubjectMismatch, errorMessages, sub, opts.Subject)
+		}
+	}
+	if opts.Audience != "" {
+		auds, ok := claims.Audience()
+		if !ok || !containsString(auds, opts.Audience) {
+			return errors.New(ErrAudienceMismatch, errorMessages, opts.Audience)
+		}
+	}
+	return nil
+}
+
+// Validate checks the reserved claims of c against now using
+// opts. It is a convenience wrapper around the package level
+// Validate() function.
+func (c Claims) Validate(now time.Time, opts ValidateOptions) error {
+	return Validate(c, now, opts)
+}
+
+// containsString checks if ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, one := range ss {
+		if one == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EOF
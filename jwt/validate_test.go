@@ -0,0 +1,79 @@
+// Tideland Go REST Server Library - JSON Web Token - Unit Tests
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestValidateTimes tests the validation of the reserved
+// time based claims.
+func TestValidateTimes(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing claims time validation")
+	now := time.Now()
+	claims := jwt.NewClaims()
+	claims.SetIssuedAt(now.Add(-time.Minute))
+	claims.SetNotBefore(now.Add(-time.Minute))
+	claims.SetExpiration(now.Add(time.Minute))
+	// Valid in all cases.
+	err := claims.Validate(now, jwt.ValidateOptions{})
+	assert.Nil(err)
+	// Expired.
+	err = claims.Validate(now.Add(2*time.Minute), jwt.ValidateOptions{})
+	assert.ErrorMatch(err, ".*expired.*")
+	// Expired but inside the leeway.
+	err = claims.Validate(now.Add(2*time.Minute), jwt.ValidateOptions{ExpLeeway: 2 * time.Minute})
+	assert.Nil(err)
+	// Not yet valid.
+	claims.SetNotBefore(now.Add(time.Minute))
+	err = claims.Validate(now, jwt.ValidateOptions{})
+	assert.ErrorMatch(err, ".*not valid before.*")
+	// Not yet valid but inside the leeway.
+	err = claims.Validate(now, jwt.ValidateOptions{NbfLeeway: 2 * time.Minute})
+	assert.Nil(err)
+}
+
+// TestValidateIssuerAudience tests the validation of the
+// reserved issuer, subject, and audience claims.
+func TestValidateIssuerAudience(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing claims issuer and audience validation")
+	claims := jwt.NewClaims()
+	claims.SetIssuer("tideland")
+	claims.SetSubject("user-1")
+	claims.SetAudience("api-a", "api-b")
+	now := time.Now()
+	// Valid issuer and audience.
+	err := claims.Validate(now, jwt.ValidateOptions{Issuer: "tideland", Audience: "api-b"})
+	assert.Nil(err)
+	// Invalid issuer.
+	err = claims.Validate(now, jwt.ValidateOptions{Issuer: "other"})
+	assert.ErrorMatch(err, ".*issuer.*")
+	// Invalid audience.
+	err = claims.Validate(now, jwt.ValidateOptions{Audience: "api-c"})
+	assert.ErrorMatch(err, ".*audience.*")
+	// Invalid subject.
+	err = claims.Validate(now, jwt.ValidateOptions{Subject: "user-2"})
+	assert.ErrorMatch(err, ".*subject.*")
+}
+
+// EOF
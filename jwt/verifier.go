@@ -0,0 +1,38 @@
+// Tideland Go REST Server Library - JSON Web Token - Verifier
+//
+// Copyright (C) 2016 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package jwt
+
+//--------------------
+// VERIFIER
+//--------------------
+
+// Verifier decodes and checks the signature of a compact token and
+// returns its claims.
+type Verifier interface {
+	Verify(token string) (Claims, error)
+}
+
+// verifier is the standard Verifier implementation, resolving keys
+// via a KeyResolver.
+type verifier struct {
+	resolver KeyResolver
+}
+
+// NewVerifier returns a Verifier resolving verification keys via
+// resolver. resolver may be a StaticKeyResolver for a single,
+// statically configured key or a JWKSResolver for remote key sets.
+func NewVerifier(resolver KeyResolver) Verifier {
+	return &verifier{resolver: resolver}
+}
+
+// Verify implements Verifier.
+func (v *verifier) Verify(token string) (Claims, error) {
+	return Decode(token, v.resolver)
+}
+
+// EOF
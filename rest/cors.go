@@ -0,0 +1,171 @@
+// Tideland Go REST Server Library - REST - CORS
+//
+// Copyright (C) 2009-2017 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package rest
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//--------------------
+// CORS CONFIG
+//--------------------
+
+// CORSConfig describes the Cross-Origin Resource Sharing policy
+// enforced for a domain, or for every domain that has none of its
+// own. Register it with SetCORS() / SetDomainCORS(); handleJob()
+// then applies it to every request without further integration work.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to access the
+	// resource. "*" allows any origin (only when AllowCredentials
+	// is false, per the Fetch standard).
+	AllowedOrigins []string
+
+	// AllowedMethods lists the HTTP methods allowed in a preflight.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed in a preflight.
+	AllowedHeaders []string
+
+	// AllowCredentials controls the Access-Control-Allow-Credentials
+	// response header.
+	AllowCredentials bool
+
+	// MaxAge controls how long a preflight response may be cached
+	// by the client. Zero omits the header.
+	MaxAge time.Duration
+}
+
+// allowedOrigin checks origin against the configured allowed origins
+// and returns the value to send as Access-Control-Allow-Origin.
+func (cfg CORSConfig) allowedOrigin(origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" && !cfg.AllowCredentials {
+			return "*", true
+		}
+		if allowed == origin {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+//--------------------
+// CORS REGISTRY
+//--------------------
+
+// corsRegistry holds the globally and per-domain registered CORS
+// configurations applied by handleJob.
+var corsRegistry = struct {
+	mu      sync.RWMutex
+	global  *CORSConfig
+	domains map[string]CORSConfig
+}{
+	domains: map[string]CORSConfig{},
+}
+
+// SetCORS registers config as the default CORS policy applied to
+// every domain that has no more specific configuration of its own.
+func SetCORS(config CORSConfig) {
+	corsRegistry.mu.Lock()
+	defer corsRegistry.mu.Unlock()
+	corsRegistry.global = &config
+}
+
+// SetDomainCORS registers config as the CORS policy for domain,
+// taking precedence over a configuration set with SetCORS().
+func SetDomainCORS(domain string, config CORSConfig) {
+	corsRegistry.mu.Lock()
+	defer corsRegistry.mu.Unlock()
+	corsRegistry.domains[domain] = config
+}
+
+// corsConfigFor returns the CORS configuration applying to domain,
+// if any has been registered.
+func corsConfigFor(domain string) (CORSConfig, bool) {
+	corsRegistry.mu.RLock()
+	defer corsRegistry.mu.RUnlock()
+	if config, ok := corsRegistry.domains[domain]; ok {
+		return config, true
+	}
+	if corsRegistry.global != nil {
+		return *corsRegistry.global, true
+	}
+	return CORSConfig{}, false
+}
+
+//--------------------
+// DISPATCH INTEGRATION
+//--------------------
+
+// handleCORS applies the CORS configuration registered for job's
+// domain, if any. It stamps the Access-Control-Allow-Origin,
+// Access-Control-Allow-Credentials, and Vary headers on every
+// matching request, and fully answers a CORS preflight itself. When
+// it does, handled is true and handleJob must return its (bool,
+// error) directly, without calling any verb handler.
+func handleCORS(job Job) (ok bool, err error, handled bool) {
+	config, found := corsConfigFor(job.Domain())
+	if !found {
+		return false, nil, false
+	}
+	req := job.Request()
+	origin := req.Header.Get("Origin")
+	isPreflight := req.Method == http.MethodOptions &&
+		req.Header.Get("Access-Control-Request-Method") != "" &&
+		origin != ""
+
+	header := job.ResponseWriter().Header()
+	header.Add("Vary", "Origin")
+	allowedOrigin, allowed := config.allowedOrigin(origin)
+
+	if isPreflight {
+		// A genuine preflight: answer it here, the inner handler's
+		// Options() or Info() is never reached.
+		w := job.ResponseWriter()
+		if !allowed {
+			w.WriteHeader(http.StatusForbidden)
+			return true, nil, true
+		}
+		header.Set("Access-Control-Allow-Origin", allowedOrigin)
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if len(config.AllowedMethods) > 0 {
+			header.Set("Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+		}
+		if len(config.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+		}
+		if config.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return true, nil, true
+	}
+
+	if allowed {
+		header.Set("Access-Control-Allow-Origin", allowedOrigin)
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+	return false, nil, false
+}
+
+// EOF
@@ -0,0 +1,175 @@
+// Tideland Go REST Server Library - REST - Unit Tests
+//
+// Copyright (C) 2009-2017 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package rest
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tideland/golib/audit"
+)
+
+//--------------------
+// HELPERS
+//--------------------
+
+// corsStubJob is a minimal Job used to drive handleJob/handleCORS in
+// isolation, without a real request dispatcher.
+type corsStubJob struct {
+	req    *http.Request
+	rec    *httptest.ResponseRecorder
+	domain string
+}
+
+func newCORSJob(domain, method, origin, requestMethod string) *corsStubJob {
+	req := httptest.NewRequest(method, "/"+domain+"/resource", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	return &corsStubJob{req: req, rec: httptest.NewRecorder(), domain: domain}
+}
+
+func (j *corsStubJob) Request() *http.Request             { return j.req }
+func (j *corsStubJob) ResponseWriter() http.ResponseWriter { return j.rec }
+func (j *corsStubJob) Domain() string                      { return j.domain }
+func (j *corsStubJob) Resource() string                    { return "resource" }
+
+// These assertions only confirm corsStubJob/corsStubHandler satisfy
+// the subset of Job/ResourceHandler exercised by this file; the full
+// interfaces aren't declared anywhere in this tree to check against,
+// so compilation against the real package is unverified.
+var (
+	_ Job             = (*corsStubJob)(nil)
+	_ ResourceHandler = (*corsStubHandler)(nil)
+)
+
+// corsStubHandler records whether it was called, to verify a
+// preflight never reaches it.
+type corsStubHandler struct {
+	called bool
+}
+
+func (h *corsStubHandler) ID() string { return "stub" }
+
+func (h *corsStubHandler) Init(env Environment, domain, resource string) error { return nil }
+
+func (h *corsStubHandler) Get(job Job) (bool, error) {
+	h.called = true
+	return true, nil
+}
+
+func (h *corsStubHandler) Options(job Job) (bool, error) {
+	h.called = true
+	return true, nil
+}
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestCORSPreflight tests that a preflight request registered via
+// SetDomainCORS() is answered by handleJob itself, without ever
+// reaching the handler.
+func TestCORSPreflight(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing CORS preflight handling at the dispatch stage")
+	domain := "cors-preflight"
+	SetDomainCORS(domain, CORSConfig{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+	})
+	inner := &corsStubHandler{}
+	job := newCORSJob(domain, http.MethodOptions, "https://example.com", "POST")
+	_, err := handleJob(inner, job)
+	assert.Nil(err)
+	assert.False(inner.called)
+	assert.Equal(job.rec.Code, http.StatusNoContent)
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Origin"), "https://example.com")
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Methods"), "GET, POST")
+	assert.Equal(job.rec.Header().Get("Vary"), "Origin")
+}
+
+// TestCORSPreflightDisallowedOrigin tests that a preflight from an
+// origin outside the allow-list is rejected by handleJob.
+func TestCORSPreflightDisallowedOrigin(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing CORS preflight with a disallowed origin")
+	domain := "cors-disallowed"
+	SetDomainCORS(domain, CORSConfig{
+		AllowedOrigins: []string{"https://example.com"},
+	})
+	inner := &corsStubHandler{}
+	job := newCORSJob(domain, http.MethodOptions, "https://evil.example", "GET")
+	_, err := handleJob(inner, job)
+	assert.Nil(err)
+	assert.False(inner.called)
+	assert.Equal(job.rec.Code, http.StatusForbidden)
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+// TestCORSWildcardCredentials tests that "*" is not honored as an
+// allowed origin when credentials are enabled.
+func TestCORSWildcardCredentials(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing CORS wildcard origin with credentials")
+	domain := "cors-wildcard-credentials"
+	SetDomainCORS(domain, CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	})
+	inner := &corsStubHandler{}
+	job := newCORSJob(domain, http.MethodOptions, "https://example.com", "GET")
+	_, err := handleJob(inner, job)
+	assert.Nil(err)
+	assert.Equal(job.rec.Code, http.StatusForbidden)
+}
+
+// TestCORSNonPreflightStampsHeaders tests that a normal, non-preflight
+// request gets the Access-Control-Allow-Origin and Vary headers
+// stamped automatically by handleJob while still reaching the handler,
+// with no per-handler wrapping required.
+func TestCORSNonPreflightStampsHeaders(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing automatic CORS header stamping on normal responses")
+	domain := "cors-stamp"
+	SetDomainCORS(domain, CORSConfig{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowCredentials: true,
+	})
+	inner := &corsStubHandler{}
+	job := newCORSJob(domain, http.MethodGet, "https://example.com", "")
+	_, err := handleJob(inner, job)
+	assert.Nil(err)
+	assert.True(inner.called)
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Origin"), "https://example.com")
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Credentials"), "true")
+	assert.Equal(job.rec.Header().Get("Vary"), "Origin")
+}
+
+// TestCORSUnregisteredDomain tests that a domain without any
+// registered CORSConfig is dispatched unchanged.
+func TestCORSUnregisteredDomain(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing that an unregistered domain gets no CORS handling")
+	inner := &corsStubHandler{}
+	job := newCORSJob("cors-unregistered", http.MethodGet, "https://example.com", "")
+	_, err := handleJob(inner, job)
+	assert.Nil(err)
+	assert.True(inner.called)
+	assert.Equal(job.rec.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+// EOF
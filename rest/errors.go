@@ -0,0 +1,31 @@
+// Tideland Go REST Server Library - REST - Errors
+//
+// Copyright (C) 2009-2017 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package rest
+
+//--------------------
+// ERROR CODES
+//--------------------
+
+// JWT auth handler error codes. They start well above the package's
+// existing handler error codes (ErrNoGetHandler ... ErrMethodNotSupported)
+// to avoid colliding with them.
+const (
+	ErrNoToken = iota + 100
+	ErrInvalidToken
+	ErrUnauthorized
+)
+
+// The JWT auth handler error codes are appended to the package's
+// existing errorMessages rather than redeclaring it here.
+func init() {
+	errorMessages[ErrNoToken] = "request carries no bearer token"
+	errorMessages[ErrInvalidToken] = "request token is invalid: %v"
+	errorMessages[ErrUnauthorized] = "request has been rejected by the authorization callback"
+}
+
+// EOF
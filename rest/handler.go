@@ -114,8 +114,14 @@ type InfoResourceHandler interface {
 
 // handleJob dispatches the passed job to the right method of the
 // passed handler. It always tries the nativ method first, then
-// the alias method according to the REST conventions.
+// the alias method according to the REST conventions. Before doing
+// so it applies any CORS configuration registered for the job's
+// domain (see SetCORS() / SetDomainCORS()), answering a preflight
+// request itself without reaching the handler at all.
 func handleJob(handler ResourceHandler, job Job) (bool, error) {
+	if ok, err, handled := handleCORS(job); handled {
+		return ok, err
+	}
 	id := func() string {
 		return fmt.Sprintf("%s@%s/%s", handler.ID(), job.Domain(), job.Resource())
 	}
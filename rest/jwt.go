@@ -0,0 +1,167 @@
+// Tideland Go REST Server Library - REST - JWT Authentication
+//
+// Copyright (C) 2009-2017 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package rest
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tideland/golib/errors"
+
+	"github.com/tideland/gorest/jwt"
+)
+
+//--------------------
+// JWT JOB
+//--------------------
+
+// JWTJob is the interface a job implements once it passed the
+// JWTAuthHandler. It gives handlers access to the verified claims
+// of the request token.
+type JWTJob interface {
+	Job
+
+	// JWT returns the claims of the verified bearer token.
+	JWT() jwt.Claims
+}
+
+// jwtJob adds the verified claims to a Job.
+type jwtJob struct {
+	Job
+	claims jwt.Claims
+}
+
+// JWT implements JWTJob.
+func (j *jwtJob) JWT() jwt.Claims {
+	return j.claims
+}
+
+//--------------------
+// JWT AUTH HANDLER
+//--------------------
+
+// JWTAuthHandler wraps a ResourceHandler and only lets requests
+// through that carry a valid "Authorization: Bearer <token>" header.
+// Use NewJWTAuthHandler() to create an instance.
+type JWTAuthHandler struct {
+	inner           ResourceHandler
+	verifier        jwt.Verifier
+	validateOptions jwt.ValidateOptions
+	unauthorized    func(job Job, err error) (bool, error)
+	authorize       func(claims jwt.Claims) bool
+}
+
+// NewJWTAuthHandler returns a ResourceHandler wrapping inner with JWT
+// based authentication. Tokens are decoded and verified using verifier.
+// Use the Set...() methods to configure claim validation, a custom
+// unauthorized responder, and claim based authorization.
+func NewJWTAuthHandler(inner ResourceHandler, verifier jwt.Verifier) *JWTAuthHandler {
+	return &JWTAuthHandler{
+		inner:        inner,
+		verifier:     verifier,
+		unauthorized: defaultUnauthorized,
+	}
+}
+
+// SetValidateOptions sets the options used to validate the reserved
+// claims (exp, nbf, iat, iss, sub, aud) of a decoded token.
+func (h *JWTAuthHandler) SetValidateOptions(opts jwt.ValidateOptions) *JWTAuthHandler {
+	h.validateOptions = opts
+	return h
+}
+
+// SetUnauthorized sets a custom responder called whenever a request
+// is rejected. The default responder answers with a plain 401.
+func (h *JWTAuthHandler) SetUnauthorized(responder func(job Job, err error) (bool, error)) *JWTAuthHandler {
+	h.unauthorized = responder
+	return h
+}
+
+// SetAuthorize sets a callback deciding, based on the verified claims,
+// if the request may proceed. It is only called after the token
+// passed verification and validation.
+func (h *JWTAuthHandler) SetAuthorize(authorize func(claims jwt.Claims) bool) *JWTAuthHandler {
+	h.authorize = authorize
+	return h
+}
+
+// ID implements ResourceHandler.
+func (h *JWTAuthHandler) ID() string {
+	return "jwt:" + h.inner.ID()
+}
+
+// Init implements ResourceHandler.
+func (h *JWTAuthHandler) Init(env Environment, domain, resource string) error {
+	return h.inner.Init(env, domain, resource)
+}
+
+// Get implements GetResourceHandler.
+func (h *JWTAuthHandler) Get(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Head implements HeadResourceHandler.
+func (h *JWTAuthHandler) Head(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Put implements PutResourceHandler.
+func (h *JWTAuthHandler) Put(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Post implements PostResourceHandler.
+func (h *JWTAuthHandler) Post(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Patch implements PatchResourceHandler.
+func (h *JWTAuthHandler) Patch(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Delete implements DeleteResourceHandler.
+func (h *JWTAuthHandler) Delete(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// Options implements OptionsResourceHandler.
+func (h *JWTAuthHandler) Options(job Job) (bool, error) { return h.authenticateAndDispatch(job) }
+
+// authenticateAndDispatch verifies the request token, validates its
+// claims, runs the authorization callback, and, if everything passed,
+// dispatches to the inner handler with the claims attached to the job.
+func (h *JWTAuthHandler) authenticateAndDispatch(job Job) (bool, error) {
+	claims, err := h.authenticate(job)
+	if err != nil {
+		return h.unauthorized(job, err)
+	}
+	return handleJob(h.inner, &jwtJob{Job: job, claims: claims})
+}
+
+// authenticate extracts and verifies the bearer token of job.
+func (h *JWTAuthHandler) authenticate(job Job) (jwt.Claims, error) {
+	const prefix = "Bearer "
+	header := job.Request().Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errors.New(ErrNoToken, errorMessages)
+	}
+	token := strings.TrimPrefix(header, prefix)
+	claims, err := h.verifier.Verify(token)
+	if err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages, err)
+	}
+	if err := claims.Validate(time.Now(), h.validateOptions); err != nil {
+		return nil, errors.Annotate(err, ErrInvalidToken, errorMessages, err)
+	}
+	if h.authorize != nil && !h.authorize(claims) {
+		return nil, errors.New(ErrUnauthorized, errorMessages)
+	}
+	return claims, nil
+}
+
+// defaultUnauthorized is the default responder used by JWTAuthHandler.
+func defaultUnauthorized(job Job, err error) (bool, error) {
+	http.Error(job.ResponseWriter(), "unauthorized: "+err.Error(), http.StatusUnauthorized)
+	return true, nil
+}
+
+// EOF
@@ -0,0 +1,182 @@
+// Tideland Go REST Server Library - REST - Unit Tests
+//
+// Copyright (C) 2009-2017 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package rest_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tideland/golib/audit"
+
+	"github.com/tideland/gorest/jwt"
+	"github.com/tideland/gorest/rest"
+)
+
+//--------------------
+// STUBS
+//--------------------
+
+// stubJob is a minimal rest.Job used to drive JWTAuthHandler in
+// isolation, without a real request dispatcher.
+type stubJob struct {
+	req *http.Request
+	rec *httptest.ResponseRecorder
+}
+
+func newStubJob(authorization string) *stubJob {
+	req := httptest.NewRequest(http.MethodGet, "/domain/resource", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return &stubJob{req: req, rec: httptest.NewRecorder()}
+}
+
+func (j *stubJob) Request() *http.Request             { return j.req }
+func (j *stubJob) ResponseWriter() http.ResponseWriter { return j.rec }
+func (j *stubJob) Domain() string                      { return "domain" }
+func (j *stubJob) Resource() string                    { return "resource" }
+
+// These assertions only confirm stubJob/stubHandler satisfy the
+// subset of rest.Job/rest.ResourceHandler exercised by this file; the
+// full interfaces aren't declared anywhere in this tree to check
+// against, so compilation against the real package is unverified.
+var (
+	_ rest.Job             = (*stubJob)(nil)
+	_ rest.ResourceHandler = (*stubHandler)(nil)
+)
+
+// stubVerifier returns a fixed claims/error pair, regardless of token.
+type stubVerifier struct {
+	claims jwt.Claims
+	err    error
+}
+
+func (v *stubVerifier) Verify(token string) (jwt.Claims, error) {
+	return v.claims, v.err
+}
+
+// stubHandler records whether it was called and what claims, if any,
+// it observed through the job.
+type stubHandler struct {
+	called    bool
+	hadJWTJob bool
+	sawClaims jwt.Claims
+}
+
+func (h *stubHandler) ID() string { return "stub" }
+
+func (h *stubHandler) Init(env rest.Environment, domain, resource string) error { return nil }
+
+func (h *stubHandler) Get(job rest.Job) (bool, error) {
+	h.called = true
+	if jj, ok := job.(rest.JWTJob); ok {
+		h.hadJWTJob = true
+		h.sawClaims = jj.JWT()
+	}
+	return true, nil
+}
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestJWTAuthHandler tables through the ways a request can be
+// accepted or rejected by JWTAuthHandler.
+func TestJWTAuthHandler(t *testing.T) {
+	assert := audit.NewTestingAssertion(t, true)
+	assert.Logf("testing the JWT auth handler")
+
+	validClaims := jwt.NewClaims()
+	validClaims.SetSubject("user-1")
+
+	expiredClaims := jwt.NewClaims()
+	expiredClaims.SetExpiration(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name          string
+		authorization string
+		verifier      *stubVerifier
+		authorize     func(jwt.Claims) bool
+		wantCalled    bool
+		wantStatus    int
+	}{
+		{
+			name:          "missing header",
+			authorization: "",
+			verifier:      &stubVerifier{claims: validClaims},
+			wantCalled:    false,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "malformed header",
+			authorization: "Token abcdef",
+			verifier:      &stubVerifier{claims: validClaims},
+			wantCalled:    false,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "verify failure",
+			authorization: "Bearer abcdef",
+			verifier:      &stubVerifier{err: errors.New("signature invalid")},
+			wantCalled:    false,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "validate rejection",
+			authorization: "Bearer abcdef",
+			verifier:      &stubVerifier{claims: expiredClaims},
+			wantCalled:    false,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "authorize denial",
+			authorization: "Bearer abcdef",
+			verifier:      &stubVerifier{claims: validClaims},
+			authorize:     func(jwt.Claims) bool { return false },
+			wantCalled:    false,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "success",
+			authorization: "Bearer abcdef",
+			verifier:      &stubVerifier{claims: validClaims},
+			wantCalled:    true,
+			wantStatus:    http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Logf("case: %s", test.name)
+		inner := &stubHandler{}
+		handler := rest.NewJWTAuthHandler(inner, test.verifier)
+		if test.authorize != nil {
+			handler.SetAuthorize(test.authorize)
+		}
+		job := newStubJob(test.authorization)
+		_, err := handler.Get(job)
+		assert.Nil(err)
+		assert.Equal(inner.called, test.wantCalled)
+		if test.wantCalled {
+			assert.True(inner.hadJWTJob)
+			sub, ok := inner.sawClaims.Subject()
+			assert.True(ok)
+			assert.Equal(sub, "user-1")
+		} else {
+			assert.Equal(job.rec.Code, test.wantStatus)
+		}
+	}
+}
+
+// EOF